@@ -0,0 +1,160 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The fillstruct command runs the fillstruct analyzer over the packages
+// named on the command line.
+//
+// fillstruct fills in a struct literal with its missing fields. It is the
+// same analysis gopls offers as a quick fix, packaged as a standalone
+// go/packages-driven binary so it can also be run in bulk over a module,
+// e.g. in CI, without an editor attached.
+//
+// Usage:
+//
+//	fillstruct [-fix] [-init-mode=zero|constructed] package...
+//	fillstruct -diff [-init-mode=zero|constructed] package...
+//
+// Without -diff, fillstruct is the ordinary singlechecker harness: -fix
+// rewrites the matched files in place using the analyzer's SuggestedFixes,
+// and each argument is resolved to a set of packages via
+// golang.org/x/tools/go/packages. -diff is handled separately (see runDiff
+// below), since the harness itself only knows how to print diagnostics or
+// apply them, not diff them: it loads the same packages itself and prints a
+// unified diff of what -fix would do, without writing anything.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/singlechecker"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/internal/diff"
+	"golang.org/x/tools/internal/lsp/analysis/fillstruct"
+)
+
+func main() {
+	if !hasBoolFlag(os.Args[1:], "diff") {
+		singlechecker.Main(fillstruct.Analyzer)
+		return
+	}
+	if err := runDiff(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// hasBoolFlag reports whether args sets the named bare boolean flag (-name
+// or --name), without otherwise parsing args. fillstruct only ever passes
+// -diff on its own, so this avoids having to teach one flag.FlagSet about
+// every flag (-fix, -c, -json, ...) the singlechecker harness defines.
+func hasBoolFlag(args []string, name string) bool {
+	for _, a := range args {
+		if a == "-"+name || a == "--"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// runDiff loads the packages named in args (after stripping fillstruct's own
+// flags) and prints, for every fillstruct suggested fix found, a unified
+// diff of the edit it would make.
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("fillstruct", flag.ExitOnError)
+	fs.Bool("diff", true, "print a unified diff of the suggested fixes instead of applying them")
+	initMode := fs.String("init-mode", "zero", "see the fillstruct analyzer's -init-mode flag")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := fillstruct.Analyzer.Flags.Set("init-mode", *initMode); err != nil {
+		return err
+	}
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+			packages.NeedImports | packages.NeedTypes | packages.NeedTypesSizes |
+			packages.NeedTypesInfo | packages.NeedSyntax,
+	}
+	pkgs, err := packages.Load(cfg, fs.Args()...)
+	if err != nil {
+		return err
+	}
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			fmt.Fprintln(os.Stderr, e)
+		}
+		if err := diffPackage(pkg, os.Stdout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffPackage runs the fillstruct analyzer over pkg by hand-assembling the
+// analysis.Pass it needs, and writes a diff for every suggested fix it
+// reports to w.
+func diffPackage(pkg *packages.Package, w io.Writer) error {
+	var reportErr error
+	pass := &analysis.Pass{
+		Analyzer:  fillstruct.Analyzer,
+		Fset:      pkg.Fset,
+		Files:     pkg.Syntax,
+		Pkg:       pkg.Types,
+		TypesInfo: pkg.TypesInfo,
+		ResultOf: map[*analysis.Analyzer]interface{}{
+			inspect.Analyzer: inspector.New(pkg.Syntax),
+		},
+		Report: func(d analysis.Diagnostic) {
+			if err := writeDiagnosticDiff(pkg.Fset, d, w); err != nil {
+				reportErr = err
+			}
+		},
+	}
+	if _, err := fillstruct.Analyzer.Run(pass); err != nil {
+		return err
+	}
+	return reportErr
+}
+
+// writeDiagnosticDiff writes a unified diff of d's first suggested fix, if
+// any, to w.
+func writeDiagnosticDiff(fset *token.FileSet, d analysis.Diagnostic, w io.Writer) error {
+	if len(d.SuggestedFixes) == 0 {
+		return nil
+	}
+	filename := fset.Position(d.Pos).Filename
+	old, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	// Apply the edits back-to-front so that earlier offsets aren't
+	// invalidated by edits made after them.
+	edits := append([]analysis.TextEdit(nil), d.SuggestedFixes[0].TextEdits...)
+	sort.Slice(edits, func(i, j int) bool { return edits[i].Pos > edits[j].Pos })
+	updated := append([]byte(nil), old...)
+	for _, e := range edits {
+		start := fset.Position(e.Pos).Offset
+		end := fset.Position(e.End).Offset
+		var buf []byte
+		buf = append(buf, updated[:start]...)
+		buf = append(buf, e.NewText...)
+		buf = append(buf, updated[end:]...)
+		updated = buf
+	}
+
+	unified := diff.Unified(filename, filename, string(old), string(updated))
+	if unified != "" {
+		_, err = io.WriteString(w, unified)
+	}
+	return err
+}
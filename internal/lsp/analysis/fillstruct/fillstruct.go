@@ -11,8 +11,11 @@ import (
 	"fmt"
 	"go/ast"
 	"go/format"
+	"go/parser"
 	"go/token"
 	"go/types"
+	"reflect"
+	"strings"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
@@ -35,6 +38,20 @@ will turn into
 		ID: 0,
 		Name: "",
 	}
+
+Structs that already have some of their fields set are also supported, as
+long as the literal uses keyed elements: the analyzer leaves the text of the
+fields the user already set completely untouched (including any trailing
+comment, except possibly on the last existing field, whose line is rewritten
+to make room for the new ones) and only appends the fields that are missing.
+
+A field's own struct tag can override how it gets filled: fill:skip or
+fill:- omits the field, fill:nil forces a nil for a pointer/slice/map/
+interface/chan/func field, fill:zero forces the basic zero value
+regardless of -init-mode, and default:"<expr>" supplies a literal Go
+expression to use instead. A struct's declaration can also carry a
+//fillstruct:defaults directive naming defaults for one or more fields,
+e.g. //fillstruct:defaults Name="anon".
 `
 
 var Analyzer = &analysis.Analyzer{
@@ -45,6 +62,21 @@ var Analyzer = &analysis.Analyzer{
 	RunDespiteErrors: true,
 }
 
+// initMode controls how populateValue picks a field's initializer: "zero"
+// (the default) uses zero/nil-ish literals (a channel field is left nil),
+// while "constructed" prefers a freshly usable value, e.g. make(...) for
+// maps and channels and a recursively-filled literal for nested structs.
+var initMode string
+
+func init() {
+	Analyzer.Flags.StringVar(&initMode, "init-mode", "zero", "value of the `mode` used to pick field initializers: \"zero\" for zero-ish values, \"constructed\" for usable constructed values (make(...), &T{...}, nested fills)")
+}
+
+// maxFillDepth bounds how many levels of nested struct/pointer fields
+// populateValue will recursively fill in "constructed" mode, so that a
+// self-referential type (e.g. a linked list node) doesn't recurse forever.
+const maxFillDepth = 3
+
 func run(pass *analysis.Pass) (interface{}, error) {
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 	nodeFilter := []ast.Node{(*ast.CompositeLit)(nil)}
@@ -55,8 +87,13 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		}
 		expr := n.(*ast.CompositeLit)
 
-		// TODO: Handle partially-filled structs as well.
-		if len(expr.Elts) != 0 {
+		// Composite literals using positional elements must already specify
+		// a value for every field, so unless the literal is empty there is
+		// nothing safe for us to rewrite: we can't tell which value goes
+		// with which field without guessing, and Go doesn't allow a
+		// "partially positional" literal to merge into anyway.
+		set, ok := keyedFieldNames(expr)
+		if !ok && len(expr.Elts) != 0 {
 			return
 		}
 
@@ -84,6 +121,7 @@ func run(pass *analysis.Pass) (interface{}, error) {
 			}
 			typ = p.Elem()
 		}
+		named, _ := typ.(*types.Named)
 		typ = typ.Underlying()
 
 		obj, ok := typ.(*types.Struct)
@@ -91,80 +129,259 @@ func run(pass *analysis.Pass) (interface{}, error) {
 			return
 		}
 		fieldCount := obj.NumFields()
-		// Skip any struct that is already populated or that has no fields.
-		if fieldCount == 0 || fieldCount == len(expr.Elts) {
+		// Skip any struct that has no fields, or that already has every
+		// settable field set (a fill:skip/fill:"-" tagged field counts as
+		// settled too: there's nothing fillstruct could ever add for it).
+		if fieldCount == 0 || allFieldsSet(obj, set, pass.Pkg) {
 			return
 		}
 
-		// Don't mutate the existing token.File. Instead, create a copy that we can use to modify
-		// position information.
-		original := pass.Fset.File(expr.Lbrace)
-		fset := token.NewFileSet()
-		tok := fset.AddFile(original.Name(), -1, original.Size())
-
-		pos := token.Pos(1)
-		var elts []ast.Expr
-		for i := 0; i < fieldCount; i++ {
-			field := obj.Field(i)
-			// Ignore fields that are not accessible in the current package.
-			if field.Pkg() != nil && field.Pkg() != pass.Pkg && !field.Exported() {
-				continue
-			}
-
-			value := populateValue(pass.Fset, file, pass.Pkg, field.Type())
-			if value == nil {
-				continue
-			}
-			pos = nextLinePos(tok, pos)
-			kv := &ast.KeyValueExpr{
-				Key: &ast.Ident{
-					NamePos: pos,
-					Name:    field.Name(),
-				},
-				Colon: pos,
-				Value: value, // 'value' has no position. fomat.Node corrects for AST nodes with no position.
-			}
-			elts = append(elts, kv)
-		}
-
-		// If all of the struct's fields are unexported, we have nothing to do.
-		if len(elts) == 0 {
-			return
-		}
-
-		cl := ast.CompositeLit{
-			Type:   expr.Type, // Don't adjust the expr.Type's position.
-			Lbrace: token.Pos(1),
-			Elts:   elts,
-			Rbrace: nextLinePos(tok, elts[len(elts)-1].Pos()),
-		}
-
-		var buf bytes.Buffer
-		if err := format.Node(&buf, fset, &cl); err != nil {
-			return
-		}
+		// A //fillstruct:defaults directive on the struct's declaration
+		// lets the type's author supply per-field defaults of their own.
+		defaults := typeDefaults(pass.Files, pass.Pkg, named)
 
 		msg := "Fill struct"
 		if name, ok := expr.Type.(*ast.Ident); ok {
 			msg = fmt.Sprintf("Fill %s", name)
 		}
 
+		var edit analysis.TextEdit
+		if len(expr.Elts) == 0 {
+			e, ok := fillEmptyLiteral(pass.Fset, file, pass.Pkg, expr, obj, defaults)
+			if !ok {
+				return
+			}
+			edit = e
+		} else {
+			e, ok := fillMissingFields(pass.Fset, file, pass.Pkg, expr, obj, set, defaults)
+			if !ok {
+				return
+			}
+			edit = e
+		}
+
 		pass.Report(analysis.Diagnostic{
 			Pos: expr.Lbrace,
 			End: expr.Rbrace,
 			SuggestedFixes: []analysis.SuggestedFix{{
-				Message: msg,
-				TextEdits: []analysis.TextEdit{{
-					Pos:     expr.Pos(),
-					End:     expr.End(),
-					NewText: buf.Bytes(),
-				}},
+				Message:   msg,
+				TextEdits: []analysis.TextEdit{edit},
 			}},
 		})
 	})
 	return nil, nil
 }
 
+// keyedFieldNames reports whether expr's elements are all keyed by a plain
+// identifier (the common "Field: value" form), and if so returns the set of
+// field names the literal already supplies a value for. A composite literal
+// using positional elements instead reports ok=false, since there is no
+// field name to key on.
+func keyedFieldNames(expr *ast.CompositeLit) (set map[string]bool, ok bool) {
+	set = make(map[string]bool, len(expr.Elts))
+	for _, elt := range expr.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, false
+		}
+		ident, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			return nil, false
+		}
+		set[ident.Name] = true
+	}
+	return set, true
+}
+
+// allFieldsSet reports whether every field of obj that pkg could set is
+// already in set or tagged fill:skip/fill:"-", meaning there is nothing
+// left for fillstruct to add. Unexported fields belonging to some other
+// package are ignored, since the same
+// field.Pkg() != pkg && !field.Exported() check elsewhere means fillstruct
+// could never have filled them in anyway.
+func allFieldsSet(obj *types.Struct, set map[string]bool, pkg *types.Package) bool {
+	for i := 0; i < obj.NumFields(); i++ {
+		field := obj.Field(i)
+		if field.Pkg() != nil && field.Pkg() != pkg && !field.Exported() {
+			continue
+		}
+		if set[field.Name()] {
+			continue
+		}
+		switch reflect.StructTag(obj.Tag(i)).Get("fill") {
+		case "skip", "-":
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// fillEmptyLiteral builds a "Type{\n\tField: value,\n...}" replacement for
+// an empty composite literal. There's no existing field text to preserve,
+// so it renders every field at once against a synthetic token.File whose
+// positions force format.Node to put one field per line; lineIndent plus
+// indentLines then correct for the fact that the synthetic positions carry
+// no indentation of their own.
+func fillEmptyLiteral(fset *token.FileSet, file *ast.File, pkg *types.Package, expr *ast.CompositeLit, obj *types.Struct, defaults map[string]ast.Expr) (analysis.TextEdit, bool) {
+	original := fset.File(expr.Lbrace)
+	synth := token.NewFileSet()
+	tok := synth.AddFile(original.Name(), -1, original.Size())
+
+	pos := token.Pos(1)
+	var elts []ast.Expr
+	for i := 0; i < obj.NumFields(); i++ {
+		field := obj.Field(i)
+		if field.Pkg() != nil && field.Pkg() != pkg && !field.Exported() {
+			continue
+		}
+		value := fieldValue(fset, file, pkg, field, obj.Tag(i), initMode, defaults, 0)
+		if value == nil {
+			continue
+		}
+		pos = nextLinePos(tok, pos)
+		elts = append(elts, &ast.KeyValueExpr{
+			Key: &ast.Ident{
+				NamePos: pos,
+				Name:    field.Name(),
+			},
+			Colon: pos,
+			Value: value, // 'value' has no position. format.Node corrects for AST nodes with no position.
+		})
+	}
+	// If all of the struct's fields are unexported, we have nothing to do.
+	if len(elts) == 0 {
+		return analysis.TextEdit{}, false
+	}
+
+	cl := ast.CompositeLit{
+		Type:   expr.Type, // Don't adjust the expr.Type's position.
+		Lbrace: token.Pos(1),
+		Elts:   elts,
+		Rbrace: nextLinePos(tok, elts[len(elts)-1].Pos()),
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, synth, &cl); err != nil {
+		return analysis.TextEdit{}, false
+	}
+
+	// format.Node has no idea how deep in the source expr.Lbrace sits,
+	// since every node in cl was given a position in the freshly
+	// created fset above. Find the indentation of the line the literal
+	// starts on and apply it to every line format.Node produced but the
+	// first (which stays where "Foo{" already was).
+	indented := indentLines(buf.Bytes(), lineIndent(fset, original, expr.Lbrace))
+
+	return analysis.TextEdit{Pos: expr.Pos(), End: expr.End(), NewText: indented}, true
+}
+
+// fillMissingFields builds a TextEdit that appends obj's fields missing
+// from set after expr's last existing element, leaving every existing
+// element's source text -- and any comment attached to it -- untouched.
+// Only the span between the last existing element and the closing brace is
+// replaced, since that's the part whose exact shape (trailing comma, brace
+// placement) depends on how the new fields get laid out; a trailing
+// comment on that last existing field is therefore the one case this can't
+// preserve.
+func fillMissingFields(fset *token.FileSet, file *ast.File, pkg *types.Package, expr *ast.CompositeLit, obj *types.Struct, set map[string]bool, defaults map[string]ast.Expr) (analysis.TextEdit, bool) {
+	var added []string
+	for i := 0; i < obj.NumFields(); i++ {
+		field := obj.Field(i)
+		if field.Pkg() != nil && field.Pkg() != pkg && !field.Exported() {
+			continue
+		}
+		if set[field.Name()] {
+			continue
+		}
+		value := fieldValue(fset, file, pkg, field, obj.Tag(i), initMode, defaults, 0)
+		if value == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := format.Node(&buf, fset, value); err != nil {
+			return analysis.TextEdit{}, false
+		}
+		added = append(added, fmt.Sprintf("%s: %s,", field.Name(), buf.String()))
+	}
+	if len(added) == 0 {
+		return analysis.TextEdit{}, false
+	}
+
+	indent := lineIndent(fset, fset.File(expr.Lbrace), expr.Lbrace)
+	var buf bytes.Buffer
+	buf.WriteString(",\n")
+	for _, line := range added {
+		buf.WriteString(indent)
+		buf.WriteString("\t")
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	buf.WriteString(indent)
+
+	last := expr.Elts[len(expr.Elts)-1]
+	return analysis.TextEdit{Pos: last.End(), End: expr.Rbrace, NewText: buf.Bytes()}, true
+}
+
+// stripPos returns a copy of expr with all position information removed.
+// Both callers (the default:"<expr>" tag and the //fillstruct:defaults
+// directive) obtain expr from parser.ParseExpr against their own throwaway
+// token.FileSet, unrelated to the one the caller will render the result
+// with; stripping positions makes the node safe to embed in -- and
+// format.Node against -- a different fset.
+func stripPos(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case nil:
+		return nil
+	case *ast.Ident:
+		return &ast.Ident{Name: e.Name}
+	case *ast.BasicLit:
+		return &ast.BasicLit{Kind: e.Kind, Value: e.Value}
+	case *ast.SelectorExpr:
+		return &ast.SelectorExpr{X: stripPos(e.X), Sel: stripPos(e.Sel).(*ast.Ident)}
+	case *ast.UnaryExpr:
+		return &ast.UnaryExpr{Op: e.Op, X: stripPos(e.X)}
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: stripPos(e.X)}
+	case *ast.ParenExpr:
+		return &ast.ParenExpr{X: stripPos(e.X)}
+	case *ast.BinaryExpr:
+		return &ast.BinaryExpr{X: stripPos(e.X), Op: e.Op, Y: stripPos(e.Y)}
+	case *ast.IndexExpr:
+		return &ast.IndexExpr{X: stripPos(e.X), Index: stripPos(e.Index)}
+	case *ast.CallExpr:
+		args := make([]ast.Expr, len(e.Args))
+		for i, a := range e.Args {
+			args[i] = stripPos(a)
+		}
+		return &ast.CallExpr{Fun: stripPos(e.Fun), Args: args, Ellipsis: e.Ellipsis}
+	case *ast.KeyValueExpr:
+		return &ast.KeyValueExpr{Key: stripPos(e.Key), Value: stripPos(e.Value)}
+	case *ast.CompositeLit:
+		var typ ast.Expr
+		if e.Type != nil {
+			typ = stripPos(e.Type)
+		}
+		elts := make([]ast.Expr, len(e.Elts))
+		for i, elt := range e.Elts {
+			elts[i] = stripPos(elt)
+		}
+		return &ast.CompositeLit{Type: typ, Elts: elts}
+	case *ast.ArrayType:
+		var l ast.Expr
+		if e.Len != nil {
+			l = stripPos(e.Len)
+		}
+		return &ast.ArrayType{Len: l, Elt: stripPos(e.Elt)}
+	case *ast.MapType:
+		return &ast.MapType{Key: stripPos(e.Key), Value: stripPos(e.Value)}
+	default:
+		// Best effort: leave anything we don't recognize untouched. Worst
+		// case go/format falls back to its own spacing for that sub-tree.
+		return expr
+	}
+}
+
 func nextLinePos(tok *token.File, pos token.Pos) token.Pos {
 	line := tok.Line(pos)
 	if line+1 > tok.LineCount() {
@@ -173,6 +390,39 @@ func nextLinePos(tok *token.File, pos token.Pos) token.Pos {
 	return tok.LineStart(line + 1)
 }
 
+// lineIndent returns the leading whitespace (tabs, in a gofmt'd file) of the
+// line containing pos in original. It returns the empty string if the
+// source can't be read, in which case indentLines is a no-op and we fall
+// back to the old flush-left rendering rather than failing the fix.
+func lineIndent(fset *token.FileSet, original *token.File, pos token.Pos) string {
+	data, err := analysisinternal.ReadFile(fset, original.Name())
+	if err != nil {
+		return ""
+	}
+	start := original.Offset(original.LineStart(original.Line(pos)))
+	end := start
+	for end < len(data) && (data[end] == ' ' || data[end] == '\t') {
+		end++
+	}
+	return string(data[start:end])
+}
+
+// indentLines prepends indent to every line of src except the first, which
+// is left alone because it's spliced in right where the original "Foo{"
+// already was. format.Node already indents the fields one level relative to
+// "Foo{", so after this they sit one level deeper than the literal's line
+// and the closing brace lines up with the start of that line.
+func indentLines(src []byte, indent string) []byte {
+	if indent == "" {
+		return src
+	}
+	lines := bytes.Split(src, []byte("\n"))
+	for i := 1; i < len(lines); i++ {
+		lines[i] = append([]byte(indent), lines[i]...)
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
 // populateValue constructs an expression to fill the value of a struct field.
 //
 // When the type of a struct field is a basic literal or interface, we return
@@ -181,7 +431,14 @@ func nextLinePos(tok *token.File, pos token.Pos) token.Pos {
 //
 // The reasoning here is that users will call fillstruct with the intention of
 // initializing the struct, in which case setting these fields to nil has no effect.
-func populateValue(fset *token.FileSet, f *ast.File, pkg *types.Package, typ types.Type) ast.Expr {
+//
+// mode selects how much further populateValue goes to make the result
+// immediately usable: "zero" sticks to the literal forms above, except that
+// a channel is left nil, while "constructed" uses make(...) for maps and
+// channels and recursively fills
+// nested struct and pointer-to-struct fields (see maxFillDepth). depth
+// counts the levels of such recursion already taken.
+func populateValue(fset *token.FileSet, f *ast.File, pkg *types.Package, typ types.Type, mode string, depth int) ast.Expr {
 	under := typ
 	if n, ok := typ.(*types.Named); ok {
 		under = n.Underlying()
@@ -204,12 +461,17 @@ func populateValue(fset *token.FileSet, f *ast.File, pkg *types.Package, typ typ
 		if k == nil || v == nil {
 			return nil
 		}
-		return &ast.CompositeLit{
-			Type: &ast.MapType{
-				Key:   k,
-				Value: v,
-			},
+		mapType := &ast.MapType{
+			Key:   k,
+			Value: v,
 		}
+		if mode == "constructed" {
+			return &ast.CallExpr{
+				Fun:  ast.NewIdent("make"),
+				Args: []ast.Expr{mapType},
+			}
+		}
+		return &ast.CompositeLit{Type: mapType}
 	case *types.Slice:
 		s := analysisinternal.TypeExpr(fset, f, pkg, u.Elem())
 		if s == nil {
@@ -237,6 +499,12 @@ func populateValue(fset *token.FileSet, f *ast.File, pkg *types.Package, typ typ
 		if v == nil {
 			return nil
 		}
+		// A channel's zero value is nil, and unlike a map or slice there's
+		// no literal form for a non-nil-but-empty one; make(...) is only
+		// warranted in constructed mode, same as for maps above.
+		if mode != "constructed" {
+			return ast.NewIdent("nil")
+		}
 		dir := ast.ChanDir(u.Dir())
 		if u.Dir() == types.SendRecv {
 			dir = ast.SEND | ast.RECV
@@ -255,6 +523,12 @@ func populateValue(fset *token.FileSet, f *ast.File, pkg *types.Package, typ typ
 		if s == nil {
 			return nil
 		}
+		if mode == "constructed" && depth < maxFillDepth {
+			return &ast.CompositeLit{
+				Type: s,
+				Elts: fillStructElts(fset, f, pkg, u, mode, depth+1),
+			}
+		}
 		return &ast.CompositeLit{
 			Type: s,
 		}
@@ -296,12 +570,169 @@ func populateValue(fset *token.FileSet, f *ast.File, pkg *types.Package, typ typ
 			Body: &ast.BlockStmt{},
 		}
 	case *types.Pointer:
+		if mode == "constructed" && depth >= maxFillDepth {
+			// Bail out of a potential cycle (e.g. a linked-list node
+			// pointing at its own type) with an explicit nil.
+			return ast.NewIdent("nil")
+		}
+		elem := populateValue(fset, f, pkg, u.Elem(), mode, depth+1)
+		if elem == nil {
+			return nil
+		}
 		return &ast.UnaryExpr{
 			Op: token.AND,
-			X:  populateValue(fset, f, pkg, u.Elem()),
+			X:  elem,
 		}
 	case *types.Interface:
 		return ast.NewIdent("nil")
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// fillStructElts builds the "Field: value" pairs for a nested struct literal
+// in "constructed" mode, recursively calling populateValue for each
+// accessible field. It mirrors the field-skipping rules in fillEmptyLiteral, and may
+// return an empty slice if every field of obj is unexported.
+func fillStructElts(fset *token.FileSet, f *ast.File, pkg *types.Package, obj *types.Struct, mode string, depth int) []ast.Expr {
+	var elts []ast.Expr
+	for i := 0; i < obj.NumFields(); i++ {
+		field := obj.Field(i)
+		if field.Pkg() != nil && field.Pkg() != pkg && !field.Exported() {
+			continue
+		}
+		// Nested fills only have the field's own struct tag to go on; a
+		// //fillstruct:defaults directive is only consulted for the
+		// top-level literal fillstruct was invoked on (see run).
+		value := fieldValue(fset, f, pkg, field, obj.Tag(i), mode, nil, depth)
+		if value == nil {
+			continue
+		}
+		elts = append(elts, &ast.KeyValueExpr{
+			Key:   &ast.Ident{Name: field.Name()},
+			Value: value,
+		})
+	}
+	return elts
+}
+
+// fieldValue decides what to put in a struct literal for field, consulting
+// (in order of precedence) its struct tag, the type-level defaults parsed
+// from a //fillstruct:defaults directive, and finally the normal
+// populateValue logic for mode.
+//
+// The recognized tags are:
+//
+//	fill:"skip" or fill:"-"   omit the field entirely
+//	fill:"nil"                force nil, for pointer/slice/map/interface/chan/func fields
+//	fill:"zero"                force the basic zero value, ignoring -init-mode
+//	default:"<go expression>"  use this expression verbatim
+func fieldValue(fset *token.FileSet, f *ast.File, pkg *types.Package, field *types.Var, tag string, mode string, defaults map[string]ast.Expr, depth int) ast.Expr {
+	st := reflect.StructTag(tag)
+	switch st.Get("fill") {
+	case "skip", "-":
+		return nil
+	case "nil":
+		if isNilable(field.Type()) {
+			return ast.NewIdent("nil")
+		}
+	case "zero":
+		return populateValue(fset, f, pkg, field.Type(), "zero", depth)
+	}
+	if d, ok := st.Lookup("default"); ok {
+		if expr, err := parser.ParseExpr(d); err == nil {
+			return stripPos(expr)
+		}
+	}
+	if expr, ok := defaults[field.Name()]; ok {
+		return expr
+	}
+	return populateValue(fset, f, pkg, field.Type(), mode, depth)
+}
+
+// isNilable reports whether typ's zero value is the predeclared nil
+// identifier, i.e. whether fill:"nil" makes sense for a field of this type.
+func isNilable(typ types.Type) bool {
+	switch typ.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Interface, *types.Chan, *types.Signature:
+		return true
+	}
+	return false
+}
+
+// fillstructDefaultsPrefix is the directive fillstruct looks for in the doc
+// comment on a struct's type declaration; see typeDefaults.
+const fillstructDefaultsPrefix = "fillstruct:defaults"
+
+// typeDefaults looks for a
+//
+//	//fillstruct:defaults Field1=expr1, Field2=expr2
+//
+// directive on named's declaration among files, and if found, returns the
+// requested default expression for each named field. It returns nil if
+// named is nil, declared outside pkg (so we have no source to read), or
+// carries no such directive.
+func typeDefaults(files []*ast.File, pkg *types.Package, named *types.Named) map[string]ast.Expr {
+	if named == nil || named.Obj().Pkg() != pkg {
+		return nil
+	}
+	pos := named.Obj().Pos()
+	for _, file := range files {
+		if file.Pos() > pos || pos > file.End() {
+			continue
+		}
+		var doc *ast.CommentGroup
+		ast.Inspect(file, func(n ast.Node) bool {
+			gd, ok := n.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				return true
+			}
+			for _, spec := range gd.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok || ts.Name.Pos() != pos {
+					continue
+				}
+				doc = ts.Doc
+				if doc == nil {
+					doc = gd.Doc
+				}
+			}
+			return true
+		})
+		if doc == nil {
+			return nil
+		}
+		return parseDefaultsDirective(doc)
+	}
+	return nil
+}
+
+// parseDefaultsDirective parses the field=expr pairs out of a
+// //fillstruct:defaults directive line in doc, if present.
+//
+// It scans doc.List directly rather than doc.Text(): Text() treats any line
+// matching the shape of a line directive (//key:rest, no space after the
+// colon) as a compiler directive and silently drops it before returning,
+// and "//fillstruct:defaults ..." matches that shape exactly.
+func parseDefaultsDirective(doc *ast.CommentGroup) map[string]ast.Expr {
+	for _, c := range doc.List {
+		line := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		rest := strings.TrimPrefix(line, fillstructDefaultsPrefix)
+		if rest == line {
+			continue // directive prefix not present on this comment
+		}
+		defaults := make(map[string]ast.Expr)
+		for _, pair := range strings.Split(rest, ",") {
+			kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			expr, err := parser.ParseExpr(strings.TrimSpace(kv[1]))
+			if err != nil {
+				continue
+			}
+			defaults[strings.TrimSpace(kv[0])] = stripPos(expr)
+		}
+		return defaults
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fillstruct_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/internal/lsp/analysis/fillstruct"
+)
+
+// TestFillStruct runs the analyzer over testdata/src/a, which exercises a
+// composite literal nested inside another composite literal, one passed as
+// a function argument, and ones used as slice/map elements with an elided
+// type -- the three shapes the indentation fix in lineIndent/indentLines
+// has to get right -- and testdata/src/b, which covers the fill:/default:
+// struct tags, the //fillstruct:defaults directive, and partial-literal
+// fills that must leave existing fields (and their comments) untouched.
+func TestFillStruct(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, fillstruct.Analyzer, "a", "b")
+}
+
+// TestFillStructConstructed runs the analyzer with -init-mode=constructed
+// over testdata/src/c, which exercises make(...) for map/chan fields and a
+// recursively-filled literal for a nested struct field, including through a
+// pointer.
+func TestFillStructConstructed(t *testing.T) {
+	if err := fillstruct.Analyzer.Flags.Set("init-mode", "constructed"); err != nil {
+		t.Fatal(err)
+	}
+	defer fillstruct.Analyzer.Flags.Set("init-mode", "zero")
+
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, fillstruct.Analyzer, "c")
+}
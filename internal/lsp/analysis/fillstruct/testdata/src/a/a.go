@@ -0,0 +1,42 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package a
+
+type Inner struct {
+	X int
+}
+
+type Outer struct {
+	In Inner
+}
+
+func call(i Inner) {}
+
+// nestedLiteral covers a composite literal nested inside another composite
+// literal, deep enough (inside a func, inside Outer's own literal) that the
+// generated fix needs more than one level of indentation.
+func nestedLiteral() {
+	_ = Outer{
+		In: Inner{}, // want "Fill Inner"
+	}
+}
+
+// argument covers a composite literal passed as a function argument, where
+// the literal shares a line with other code instead of starting the line.
+func argument() {
+	call(Inner{}) // want "Fill Inner"
+}
+
+// elements and keyed cover composite literals used as slice and map
+// elements, where the literal's type is elided and taken from the
+// slice/map's own element type.
+var (
+	elements = []Inner{
+		{}, // want "Fill struct"
+	}
+	keyed = map[string]Inner{
+		"k": {}, // want "Fill struct"
+	}
+)
@@ -0,0 +1,44 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package b
+
+// Config carries a //fillstruct:defaults directive naming a default for
+// Name, alongside per-field fill tags covering the rest of the tag
+// behaviors.
+//
+//fillstruct:defaults Name="anon"
+type Config struct {
+	Name    string
+	Secret  string         `fill:"skip"`
+	Cache   map[string]int `fill:"nil"`
+	Count   int            `fill:"zero"`
+	Timeout int            `default:"30"`
+}
+
+// Skippable has nothing left to fill once A is set: Hidden is tagged
+// fill:"skip", so allFieldsSet must not keep re-offering a no-op fix.
+type Skippable struct {
+	A      int
+	Hidden string `fill:"skip"`
+}
+
+// Triple exercises the partial-fill path: C is missing, but A and B are
+// already keyed and must come through untouched, comment included.
+type Triple struct {
+	A int
+	B int
+	C string
+}
+
+func tags() {
+	_ = Config{} // want "Fill Config"
+
+	_ = Skippable{A: 1}
+
+	_ = Triple{ // want "Fill Triple"
+		A: 1, // keep me
+		B: 2,
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package c
+
+type Leaf struct {
+	N int
+}
+
+// Box is filled under -init-mode=constructed: M and Ch must come out as
+// make(...) rather than the zero-mode {}/nil, and Leaf/P must come out as
+// recursively-filled values rather than empty literals.
+type Box struct {
+	M    map[string]int
+	Ch   chan int
+	Leaf Leaf
+	P    *Leaf
+}
+
+func constructed() {
+	_ = Box{} // want "Fill Box"
+}